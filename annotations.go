@@ -0,0 +1,63 @@
+package main
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// parseAnnotations splits a doc comment group into plain prose and
+// structured annotation lines. Two annotation styles are recognized:
+// swaggo-style "@Tag rest" (e.g. "@Summary", "@Param", "@Success",
+// "@Router") and Go compiler directives "//go:tag rest" (e.g.
+// "//go:generate", "//go:build", "//go:embed"). Annotation keys drop
+// the "@" prefix but keep the "go:" prefix, matching how each style is
+// normally referenced in prose.
+func parseAnnotations(group *ast.CommentGroup) (doc string, annotations map[string][]string) {
+	annotations = map[string][]string{}
+	var docLines []string
+
+	for _, c := range group.List {
+		text := strings.TrimSpace(stripCommentMarkers(c.Text))
+		if text == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "go:"):
+			key, val := splitAnnotation(text)
+			annotations[key] = append(annotations[key], val)
+		case strings.HasPrefix(text, "@"):
+			key, val := splitAnnotation(text[1:])
+			annotations[key] = append(annotations[key], val)
+		default:
+			docLines = append(docLines, text)
+		}
+	}
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	return strings.Join(docLines, "\n"), annotations
+}
+
+// stripCommentMarkers removes the leading "//" or surrounding "/* */"
+// from a single *ast.Comment's Text.
+func stripCommentMarkers(text string) string {
+	switch {
+	case strings.HasPrefix(text, "//"):
+		return strings.TrimPrefix(text, "//")
+	case strings.HasPrefix(text, "/*"):
+		return strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+	}
+	return text
+}
+
+// splitAnnotation splits "Key rest of line" into ("Key", "rest of line").
+func splitAnnotation(text string) (key, val string) {
+	parts := strings.SplitN(text, " ", 2)
+	key = parts[0]
+	if len(parts) > 1 {
+		val = strings.TrimSpace(parts[1])
+	}
+	return key, val
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDocForUngroupedDecl(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"// Top is a top-level var.\n"+
+		"var Top = 1\n")
+
+	tags, err := Parse(filepath.Join(dir, "sample.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(tags) != 1 || tags[0].Doc != "Top is a top-level var." {
+		t.Fatalf("Top.Doc = %+v, want a single tag with the var's doc comment", tags)
+	}
+}
+
+func TestDocForGroupedDeclPerSpec(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"const (\n"+
+		"\t// A is the first constant.\n"+
+		"\tA = 1\n"+
+		"\t// B is the second constant.\n"+
+		"\tB = 2\n"+
+		")\n")
+
+	tags, err := Parse(filepath.Join(dir, "sample.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	byName := map[string]*Tag{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+	if byName["A"].Doc != "A is the first constant." {
+		t.Fatalf("A.Doc = %q, want %q", byName["A"].Doc, "A is the first constant.")
+	}
+	if byName["B"].Doc != "B is the second constant." {
+		t.Fatalf("B.Doc = %q, want %q", byName["B"].Doc, "B is the second constant.")
+	}
+}
+
+func TestParseAnnotationsSwaggoAndGoDirectives(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"// ListUsers returns all users.\n"+
+		"// @Summary List users\n"+
+		"// @Router /users [get]\n"+
+		"//go:generate mockgen -source=sample.go\n"+
+		"func ListUsers() {}\n")
+
+	tags, err := Parse(filepath.Join(dir, "sample.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(tags) != 1 {
+		t.Fatalf("expected one tag, got %+v", tags)
+	}
+	tag := tags[0]
+
+	if tag.Doc != "ListUsers returns all users." {
+		t.Fatalf("Doc = %q, want only the prose line", tag.Doc)
+	}
+	if got := tag.Annotations["Summary"]; len(got) != 1 || got[0] != "List users" {
+		t.Fatalf("Annotations[Summary] = %+v, want [\"List users\"]", got)
+	}
+	if got := tag.Annotations["Router"]; len(got) != 1 || got[0] != "/users [get]" {
+		t.Fatalf("Annotations[Router] = %+v, want [\"/users [get]\"]", got)
+	}
+	if got := tag.Annotations["go:generate"]; len(got) != 1 || got[0] != "mockgen -source=sample.go" {
+		t.Fatalf("Annotations[go:generate] = %+v, want [\"mockgen -source=sample.go\"]", got)
+	}
+}
+
+func TestSplitAnnotation(t *testing.T) {
+	tests := []struct {
+		text    string
+		wantKey string
+		wantVal string
+	}{
+		{"Router /users [get]", "Router", "/users [get]"},
+		{"generate mockgen -source=sample.go", "generate", "mockgen -source=sample.go"},
+		{"Summary", "Summary", ""},
+	}
+	for _, tt := range tests {
+		key, val := splitAnnotation(tt.text)
+		if key != tt.wantKey || val != tt.wantVal {
+			t.Errorf("splitAnnotation(%q) = (%q, %q), want (%q, %q)", tt.text, key, val, tt.wantKey, tt.wantVal)
+		}
+	}
+}
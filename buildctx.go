@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultGOOS, defaultGOARCH and defaultCgo enumerate the matrix
+// ParsePackage scans when the caller hasn't pinned a single context.
+var (
+	defaultGOOS   = []string{"linux", "darwin", "windows"}
+	defaultGOARCH = []string{"amd64", "arm64", "386"}
+	defaultCgo    = []bool{true, false}
+)
+
+// DefaultContexts returns one *build.Context per combination of
+// defaultGOOS x defaultGOARCH x defaultCgo.
+func DefaultContexts() []*build.Context {
+	ctxs := make([]*build.Context, 0, len(defaultGOOS)*len(defaultGOARCH)*len(defaultCgo))
+	for _, goos := range defaultGOOS {
+		for _, goarch := range defaultGOARCH {
+			for _, cgo := range defaultCgo {
+				ctx := build.Default
+				ctx.GOOS = goos
+				ctx.GOARCH = goarch
+				ctx.CgoEnabled = cgo
+				ctxs = append(ctxs, &ctx)
+			}
+		}
+	}
+	return ctxs
+}
+
+// ParsePackage parses every *.go file directly in dir that matches at
+// least one of ctxs, merging the results into one []*Tag. A file that
+// matches every context contributes tags with no build annotation; a
+// file that only matches some contexts has its tags' Fields["build"]
+// set to the list of "GOOS:x,GOARCH:y" contexts it was found under, so
+// ctags consumers can tell platform-specific symbols apart from
+// portable ones.
+func ParsePackage(dir string, ctxs []*build.Context) ([]*Tag, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		files = append(files, e.Name())
+	}
+
+	var tags []*Tag
+	for _, file := range files {
+		var matched []*build.Context
+		for _, ctx := range ctxs {
+			ok, err := ctx.MatchFile(dir, file)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, ctx)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		ts, err := Parse(filepath.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		if len(matched) < len(ctxs) {
+			build := contextLabel(matched)
+			for _, t := range ts {
+				t.Fields["build"] = build
+			}
+		}
+		tags = append(tags, ts...)
+	}
+
+	return tags, nil
+}
+
+// contextLabel renders the contexts a platform-gated file matched under
+// as a stable, deduplicated "GOOS:x,GOARCH:y" list.
+func contextLabel(ctxs []*build.Context) string {
+	seen := map[string]bool{}
+	labels := make([]string, 0, len(ctxs))
+	for _, ctx := range ctxs {
+		l := fmt.Sprintf("GOOS:%s,GOARCH:%s", ctx.GOOS, ctx.GOARCH)
+		if !seen[l] {
+			seen[l] = true
+			labels = append(labels, l)
+		}
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ";")
+}
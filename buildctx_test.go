@@ -0,0 +1,68 @@
+package main
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePackageDefaultMatrixTagsPlatformSpecificSymbols(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "common.go", "package sample\n\nfunc Common() {}\n")
+	writeFile(t, dir, "common_windows.go", "package sample\n\nfunc WindowsOnly() {}\n")
+
+	tags, err := ParsePackage(dir, DefaultContexts())
+	if err != nil {
+		t.Fatalf("ParsePackage: %v", err)
+	}
+
+	byName := map[string]*Tag{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+
+	common, ok := byName["Common"]
+	if !ok {
+		t.Fatalf("Common tag not found in %+v", tags)
+	}
+	if common.Fields["build"] != "" {
+		t.Fatalf("Common is portable, should have no build field, got %q", common.Fields["build"])
+	}
+
+	win, ok := byName["WindowsOnly"]
+	if !ok {
+		t.Fatalf("WindowsOnly tag not found in %+v", tags)
+	}
+	if !strings.Contains(win.Fields["build"], "GOOS:windows") {
+		t.Fatalf("WindowsOnly build field = %q, want it to mention GOOS:windows", win.Fields["build"])
+	}
+}
+
+func TestParsePackagePinnedContextExcludesNonMatchingFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "common.go", "package sample\n\nfunc Common() {}\n")
+	writeFile(t, dir, "common_windows.go", "package sample\n\nfunc WindowsOnly() {}\n")
+
+	ctx := build.Default
+	ctx.GOOS = "linux"
+	ctx.GOARCH = "amd64"
+
+	tags, err := ParsePackage(dir, []*build.Context{&ctx})
+	if err != nil {
+		t.Fatalf("ParsePackage: %v", err)
+	}
+	for _, tag := range tags {
+		if tag.Name == "WindowsOnly" {
+			t.Fatalf("WindowsOnly should be excluded from a linux/amd64-only context, got %+v", tags)
+		}
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile(%s): %v", name, err)
+	}
+}
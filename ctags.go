@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ctagsKind maps a Tag's Type to the short kind letter/word used in the
+// ctags "kind" column, following the conventions exuberant/universal-ctags
+// uses for its Go parser.
+var ctagsKind = map[string]string{
+	Function:        "f",
+	Method:          "m",
+	Struct:          "s",
+	Interface:       "i",
+	Type:            "t",
+	Constant:        "c",
+	Variable:        "v",
+	Import:          "I",
+	Field:           "w",
+	InterfaceMethod: "n",
+}
+
+// WriteCtags writes tags as a sorted exuberant/universal-ctags tag file
+// (the extended "!_TAG_FILE_FORMAT 2" format understood by vim and most
+// editors that shell out to ctags).
+func WriteCtags(w io.Writer, tags []*Tag) error {
+	sorted := make([]*Tag, len(tags))
+	copy(sorted, tags)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].File < sorted[j].File
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "!_TAG_FILE_FORMAT\t2\t/extended format/")
+	fmt.Fprintln(bw, "!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/")
+
+	for _, t := range sorted {
+		kind := ctagsKind[t.Type]
+		if kind == "" {
+			kind = "?"
+		}
+		fmt.Fprintf(bw, "%s\t%s\t/^%s$/;\"\t%s%s\n",
+			t.Name, t.File, escapeCtagsPattern(t.Pattern), kind, ctagsExtFields(t))
+	}
+	return bw.Flush()
+}
+
+// ctagsExtFields renders t.Fields (parent scope, access, build context,
+// ...) as trailing tab-separated "key:value" extension fields.
+func ctagsExtFields(t *Tag) string {
+	if len(t.Fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(t.Fields))
+	for k := range t.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\t%s:%s", k, t.Fields[k])
+	}
+	return b.String()
+}
+
+func escapeCtagsPattern(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "/", "\\/")
+	return s
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCtags(t *testing.T) {
+	tags := []*Tag{
+		{Name: "Bar", File: "b.go", Type: Function, Pattern: "func Bar() {}", Fields: map[string]string{"access": "public"}},
+		{Name: "Foo", File: "a.go", Type: Method, Pattern: `func (s *S) Foo() {}`, Fields: map[string]string{"access": "public"}},
+	}
+
+	var buf strings.Builder
+	if err := WriteCtags(&buf, tags); err != nil {
+		t.Fatalf("WriteCtags: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "!_TAG_FILE_FORMAT\t2\t") {
+		t.Fatalf("missing ctags header: %q", out)
+	}
+
+	fooIdx := strings.Index(out, "Foo\t")
+	barIdx := strings.Index(out, "Bar\t")
+	if fooIdx == -1 || barIdx == -1 || barIdx > fooIdx {
+		t.Fatalf("tags not sorted by name (Bar before Foo): %q", out)
+	}
+
+	if !strings.Contains(out, "Bar\tb.go\t/^func Bar() {}$/;\"\tf\taccess:public\n") {
+		t.Fatalf("unexpected Bar line: %q", out)
+	}
+	if !strings.Contains(out, "Foo\ta.go\t/^func (s *S) Foo() {}$/;\"\tm\taccess:public\n") {
+		t.Fatalf("unexpected Foo line: %q", out)
+	}
+}
+
+func TestEscapeCtagsPattern(t *testing.T) {
+	got := escapeCtagsPattern(`a/b\c`)
+	want := `a\/b\\c`
+	if got != want {
+		t.Fatalf("escapeCtagsPattern() = %q, want %q", got, want)
+	}
+}
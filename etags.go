@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteEtags writes tags as an Emacs etags "TAGS" file: one section per
+// source file, framed by a form-feed line, containing one tag entry per
+// line in "pattern\x7fname\x01line,byte" form.
+func WriteEtags(w io.Writer, tags []*Tag) error {
+	var order []string
+	byFile := make(map[string][]*Tag)
+	for _, t := range tags {
+		if _, ok := byFile[t.File]; !ok {
+			order = append(order, t.File)
+		}
+		byFile[t.File] = append(byFile[t.File], t)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, file := range order {
+		section := etagsSection(byFile[file])
+		fmt.Fprintf(bw, "\x0c\n%s,%d\n", file, len(section))
+		bw.WriteString(section)
+	}
+	return bw.Flush()
+}
+
+// etagsSection renders the body of one file's TAGS section.
+func etagsSection(tags []*Tag) string {
+	var b []byte
+	for _, t := range tags {
+		b = append(b, t.Pattern...)
+		b = append(b, '\x7f')
+		b = append(b, t.Name...)
+		b = append(b, '\x01')
+		b = append(b, fmt.Sprintf("%d,%d", t.Line, t.Offset)...)
+		b = append(b, '\n')
+	}
+	return string(b)
+}
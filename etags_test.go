@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteEtags(t *testing.T) {
+	tags := []*Tag{
+		{Name: "Foo", File: "a.go", Pattern: "func Foo() {}", Line: 3, Offset: 20},
+		{Name: "Bar", File: "a.go", Pattern: "func Bar() {}", Line: 7, Offset: 60},
+	}
+
+	var buf strings.Builder
+	if err := WriteEtags(&buf, tags); err != nil {
+		t.Fatalf("WriteEtags: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "\x0c\na.go,") {
+		t.Fatalf("missing file section header: %q", out)
+	}
+	if !strings.Contains(out, "func Foo() {}\x7fFoo\x013,20\n") {
+		t.Fatalf("missing Foo record: %q", out)
+	}
+	if !strings.Contains(out, "func Bar() {}\x7fBar\x017,60\n") {
+		t.Fatalf("missing Bar record: %q", out)
+	}
+}
@@ -3,102 +3,176 @@ package main
 import (
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
+	"go/build"
 	"os"
-	"strconv"
+	"path/filepath"
+	"regexp"
+	"strings"
 )
 
-type Tag struct {
-	Name     string
-	File     string
-	Start    string
-	End      string
-	Type     string
-	RecvType []string
-	RecvName [][]string
-}
+var flags = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 
-// Tag types.
-const (
-	Method   string = "method"
-	Function string = "function"
-)
+var (
+	format = flags.String("f", "", "tag output format: ctags, etags (default: Go-syntax dump)")
 
-type tagParser struct {
-	fset  *token.FileSet
-	tags  []*Tag
-	types []string // all types we encounter, used to determine the constructors
-}
+	goosFlag   = flags.String("goos", "", "pin GOOS for directory args (default: scan the linux/darwin/windows matrix)")
+	goarchFlag = flags.String("goarch", "", "pin GOARCH for directory args (default: scan the amd64/arm64/386 matrix)")
+	tagsFlag   = flags.String("tags", "", "comma-separated build tags, as with go build -tags")
+	cgoFlag    = flags.Bool("cgo", false, "pin cgo on/off for directory args (default: scan both)")
 
-func Parse(filename string) ([]*Tag, error) {
-	p := &tagParser{
-		fset:  token.NewFileSet(),
-		tags:  []*Tag{},
-		types: make([]string, 0),
+	genFlag  = flags.String("gen", "", "code generation mode: tests")
+	onlyFlag = flags.String("only", "", "-gen tests: only generate tests for funcs matching this regexp")
+	exclFlag = flags.String("excl", "", "-gen tests: skip funcs matching this regexp")
+	ifaceGen = flags.Bool("i", false, "-gen tests: stub locally-declared interface parameters instead of using nil")
+)
+
+func main() {
+	if err := flags.Parse(os.Args[1:]); err == flag.ErrHelp {
+		return
 	}
 
-	f, err := parser.ParseFile(p.fset, filename, nil, 0)
-	if err != nil {
-		return nil, err
+	if *genFlag == "tests" {
+		if err := runGenTests(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
-	p.parseDeclarations(f)
 
-	return p.tags, nil
-}
+	ctxs := buildContexts()
 
-func (p *tagParser) parseDeclarations(f *ast.File) {
-	for _, d := range f.Decls {
-		if decl, ok := d.(*ast.FuncDecl); ok {
-			p.parseFunc(decl)
+	tags := []*Tag{}
+	for _, arg := range flags.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			ts, err := ParsePackage(arg, ctxs)
+			if err != nil {
+				continue
+			}
+			tags = append(tags, ts...)
+			continue
 		}
+		ts, err := Parse(arg)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, ts...)
 	}
-}
 
-func (p *tagParser) parseFunc(f *ast.FuncDecl) {
-	tag := p.createTag(f.Name.Name, f.Pos(), f.End(), Function)
-	if f.Recv != nil && len(f.Recv.List) > 0 {
-		// this function has a receiver, set the type to Method
-		tag.Type = Method
-		for i, v := range f.Recv.List {
-			tag.RecvType = append(tag.RecvType, fmt.Sprint(v.Type))
-			for _, v2 := range v.Names {
-				tag.RecvName[i] = append(tag.RecvName[i], v2.Name)
-			}
+	var err error
+	switch *format {
+	case "ctags":
+		err = WriteCtags(os.Stdout, tags)
+	case "etags":
+		err = WriteEtags(os.Stdout, tags)
+	case "":
+		for _, v := range tags {
+			fmt.Println(v)
 		}
+	default:
+		err = fmt.Errorf("unknown tag format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	p.tags = append(p.tags, tag)
 }
 
-func (p *tagParser) createTag(name string, start, end token.Pos, tagType string) *Tag {
-	f := p.fset.File(start).Name()
-	return &Tag{
-		Name:     name,
-		File:     f,
-		Start:    strconv.Itoa(p.fset.Position(start).Line),
-		End:      strconv.Itoa(p.fset.Position(end).Line),
-		Type:     tagType,
-		RecvType: []string{},
-		RecvName: [][]string{{}},
+// runGenTests drives -gen tests over every file argument (expanding
+// directory arguments to their non-test *.go files).
+func runGenTests() error {
+	var only, excl *regexp.Regexp
+	if *onlyFlag != "" {
+		re, err := regexp.Compile(*onlyFlag)
+		if err != nil {
+			return fmt.Errorf("-only: %w", err)
+		}
+		only = re
+	}
+	if *exclFlag != "" {
+		re, err := regexp.Compile(*exclFlag)
+		if err != nil {
+			return fmt.Errorf("-excl: %w", err)
+		}
+		excl = re
 	}
-}
 
-var flags = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	files, err := genTestFiles(flags.Args())
+	if err != nil {
+		return err
+	}
 
-func main() {
-	if err := flags.Parse(os.Args[1:]); err == flag.ErrHelp {
-		return
+	for _, file := range files {
+		tags, err := Parse(file)
+		if err != nil {
+			return err
+		}
+		if err := GenerateTests(file, tags, only, excl, *ifaceGen); err != nil {
+			return err
+		}
 	}
-	tags := []*Tag{}
-	for _, file := range flags.Args() {
-		ts, err := Parse(file)
+	return nil
+}
+
+// genTestFiles expands args (files and directories) into the set of
+// non-test *.go source files -gen tests should run over.
+func genTestFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
 		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
 			continue
 		}
-		tags = append(tags, ts...)
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+				continue
+			}
+			files = append(files, filepath.Join(arg, e.Name()))
+		}
+	}
+	return files, nil
+}
+
+// buildContexts returns the build contexts directory scans should use:
+// a single context pinned by -goos/-goarch/-tags/-cgo if any of those
+// were passed, or the default matrix otherwise.
+func buildContexts() []*build.Context {
+	pinned := false
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "goos", "goarch", "tags", "cgo":
+			pinned = true
+		}
+	})
+	if !pinned {
+		return DefaultContexts()
 	}
-	for _, v := range tags {
-		fmt.Println(v)
+
+	ctx := build.Default
+	if *goosFlag != "" {
+		ctx.GOOS = *goosFlag
 	}
+	if *goarchFlag != "" {
+		ctx.GOARCH = *goarchFlag
+	}
+	if *tagsFlag != "" {
+		ctx.BuildTags = strings.Split(*tagsFlag, ",")
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "cgo" {
+			ctx.CgoEnabled = *cgoFlag
+		}
+	})
+	return []*build.Context{&ctx}
 }
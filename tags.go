@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Tag struct {
+	Name     string
+	File     string
+	Start    string
+	End      string
+	Type     string
+	RecvType []string
+	RecvName [][]string
+
+	// Pattern is the literal source line the tag was found on, used as
+	// the ctags/etags search pattern.
+	Pattern string
+	// Line and Offset locate Pattern within File: Line is 1-based,
+	// Offset is the 0-based byte offset of the line's start.
+	Line   int
+	Offset int
+
+	// Fields holds extension data for hierarchical navigation, e.g.
+	// "struct":"Foo" or "interface":"Bar" for the parent scope of a
+	// field/method tag, and "access":"public"/"private".
+	Fields map[string]string
+
+	// Doc is the tag's doc comment with any @annotation/go: directive
+	// lines stripped out, and Annotations holds those lines, keyed by
+	// their tag (e.g. "Router", "go:generate") with the "@"/"go:"
+	// prefix kept off "@..." keys but on "go:..." keys.
+	Doc         string
+	Annotations map[string][]string
+
+	// Exported, Params, Results and RecvPtr enrich Function/Method (and
+	// InterfaceMethod) tags for the test-skeleton generator: Exported
+	// mirrors ast.Ident.IsExported, Params/Results are the func's
+	// signature rendered by go/printer, and RecvPtr reports whether a
+	// Method's receiver is a pointer.
+	Exported bool
+	Params   []Param
+	Results  []Param
+	RecvPtr  bool
+}
+
+// Param is a single function parameter or result. Type is the element
+// type with any "..." stripped off; Variadic records whether the
+// original parameter was declared as "...Type".
+type Param struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+// Tag types.
+const (
+	Method          string = "method"
+	Function        string = "function"
+	Struct          string = "struct"
+	Interface       string = "interface"
+	Type            string = "type"
+	Constant        string = "constant"
+	Variable        string = "variable"
+	Import          string = "import"
+	Field           string = "field"
+	InterfaceMethod string = "interfaceMethod"
+)
+
+type tagParser struct {
+	fset  *token.FileSet
+	tags  []*Tag
+	types []string // all types we encounter, used to determine the constructors
+	lines [][]byte // source split on '\n', for ctags/etags patterns
+	cmap  ast.CommentMap
+}
+
+func Parse(filename string) ([]*Tag, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &tagParser{
+		fset:  token.NewFileSet(),
+		tags:  []*Tag{},
+		types: make([]string, 0),
+		lines: bytes.Split(src, []byte("\n")),
+	}
+
+	f, err := parser.ParseFile(p.fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	p.cmap = ast.NewCommentMap(p.fset, f, f.Comments)
+	p.parseDeclarations(f)
+
+	return p.tags, nil
+}
+
+func (p *tagParser) parseDeclarations(f *ast.File) {
+	for _, d := range f.Decls {
+		switch decl := d.(type) {
+		case *ast.FuncDecl:
+			p.parseFunc(decl)
+		case *ast.GenDecl:
+			p.parseGenDecl(decl)
+		}
+	}
+}
+
+func (p *tagParser) parseFunc(f *ast.FuncDecl) {
+	tag := p.createTag(f.Name.Name, f.Pos(), f.End(), Function)
+	tag.Exported = f.Name.IsExported()
+	tag.Params = p.renderFields(f.Type.Params)
+	tag.Results = p.renderFields(f.Type.Results)
+	if f.Recv != nil && len(f.Recv.List) > 0 {
+		// this function has a receiver, set the type to Method
+		tag.Type = Method
+		for i, v := range f.Recv.List {
+			recvType := v.Type
+			if star, ok := recvType.(*ast.StarExpr); ok {
+				tag.RecvPtr = true
+				recvType = star.X
+			}
+			tag.RecvType = append(tag.RecvType, p.renderExpr(recvType))
+			for _, v2 := range v.Names {
+				tag.RecvName[i] = append(tag.RecvName[i], v2.Name)
+			}
+		}
+	}
+	tag.Doc, tag.Annotations = p.docFor(f)
+	p.tags = append(p.tags, tag)
+}
+
+// renderFields converts a parameter/result field list into []Param,
+// rendering each field's type via go/printer and splitting grouped
+// names ("a, b int") into one Param per name. A trailing "...T"
+// parameter is reported as Variadic with Type "T", not "...T", so
+// callers can decide how to render the "..." for their context (a
+// struct field vs. a call's argument list). The blank identifier "_"
+// is reported as an unnamed field, same as an entirely unnamed param.
+func (p *tagParser) renderFields(list *ast.FieldList) []Param {
+	if list == nil {
+		return nil
+	}
+	var params []Param
+	for _, f := range list.List {
+		typExpr := f.Type
+		variadic := false
+		if ell, ok := typExpr.(*ast.Ellipsis); ok {
+			variadic = true
+			typExpr = ell.Elt
+		}
+		typ := p.renderExpr(typExpr)
+
+		if len(f.Names) == 0 {
+			params = append(params, Param{Type: typ, Variadic: variadic})
+			continue
+		}
+		for _, n := range f.Names {
+			name := n.Name
+			if name == "_" {
+				name = ""
+			}
+			params = append(params, Param{Name: name, Type: typ, Variadic: variadic})
+		}
+	}
+	return params
+}
+
+// renderExpr renders an AST type expression back to Go source text.
+func (p *tagParser) renderExpr(e ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, p.fset, e); err != nil {
+		return fmt.Sprint(e)
+	}
+	return buf.String()
+}
+
+// parseGenDecl handles TYPE, CONST, VAR and IMPORT declarations, which
+// covers everything parseFunc doesn't. Doc comments are looked up per
+// spec, since ast.CommentMap attaches a grouped declaration's per-item
+// doc comments (e.g. each entry of a "const ( ... )" block) to that
+// item's own ValueSpec/TypeSpec/ImportSpec rather than to the outer
+// GenDecl; declDoc is used as a fallback for the common single-spec
+// case, where the doc comment instead attaches to the GenDecl itself.
+func (p *tagParser) parseGenDecl(d *ast.GenDecl) {
+	declDoc, declAnnotations := p.docFor(d)
+	for _, spec := range d.Specs {
+		doc, annotations := p.docFor(spec)
+		if doc == "" && annotations == nil {
+			doc, annotations = declDoc, declAnnotations
+		}
+		switch d.Tok {
+		case token.TYPE:
+			p.parseTypeSpec(spec.(*ast.TypeSpec), doc, annotations)
+		case token.CONST:
+			p.parseValueSpec(spec.(*ast.ValueSpec), Constant, doc, annotations)
+		case token.VAR:
+			p.parseValueSpec(spec.(*ast.ValueSpec), Variable, doc, annotations)
+		case token.IMPORT:
+			p.parseImportSpec(spec.(*ast.ImportSpec), doc, annotations)
+		}
+	}
+}
+
+func (p *tagParser) parseTypeSpec(spec *ast.TypeSpec, doc string, annotations map[string][]string) {
+	p.types = append(p.types, spec.Name.Name)
+
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		tag := p.createTag(spec.Name.Name, spec.Pos(), spec.End(), Struct)
+		tag.Doc, tag.Annotations = doc, annotations
+		p.tags = append(p.tags, tag)
+		p.parseStructFields(spec.Name.Name, t)
+	case *ast.InterfaceType:
+		tag := p.createTag(spec.Name.Name, spec.Pos(), spec.End(), Interface)
+		tag.Doc, tag.Annotations = doc, annotations
+		p.tags = append(p.tags, tag)
+		p.parseInterfaceMethods(spec.Name.Name, t)
+	default:
+		tag := p.createTag(spec.Name.Name, spec.Pos(), spec.End(), Type)
+		tag.Doc, tag.Annotations = doc, annotations
+		p.tags = append(p.tags, tag)
+	}
+}
+
+func (p *tagParser) parseValueSpec(spec *ast.ValueSpec, tagType string, doc string, annotations map[string][]string) {
+	for _, name := range spec.Names {
+		if name.Name == "_" {
+			continue
+		}
+		tag := p.createTag(name.Name, name.Pos(), spec.End(), tagType)
+		tag.Doc, tag.Annotations = doc, annotations
+		p.tags = append(p.tags, tag)
+	}
+}
+
+func (p *tagParser) parseImportSpec(spec *ast.ImportSpec, doc string, annotations map[string][]string) {
+	name := spec.Path.Value
+	if spec.Name != nil {
+		name = spec.Name.Name
+	}
+	tag := p.createTag(name, spec.Pos(), spec.End(), Import)
+	tag.Doc, tag.Annotations = doc, annotations
+	p.tags = append(p.tags, tag)
+}
+
+// parseStructFields walks a struct's fields, tagging named fields and
+// embedded types alike with their parent struct recorded in Fields.
+func (p *tagParser) parseStructFields(structName string, t *ast.StructType) {
+	if t.Fields == nil {
+		return
+	}
+	for _, f := range t.Fields.List {
+		if len(f.Names) == 0 {
+			// embedded type: the type expression is itself the field name.
+			tag := p.createTag(p.renderExpr(f.Type), f.Pos(), f.End(), Field)
+			tag.Fields["struct"] = structName
+			tag.Fields["embedded"] = "true"
+			p.tags = append(p.tags, tag)
+			continue
+		}
+		for _, name := range f.Names {
+			tag := p.createTag(name.Name, name.Pos(), f.End(), Field)
+			tag.Fields["struct"] = structName
+			p.tags = append(p.tags, tag)
+		}
+	}
+}
+
+// parseInterfaceMethods walks an interface's method set, tagging methods
+// and embedded interfaces with their parent interface recorded in Fields.
+func (p *tagParser) parseInterfaceMethods(ifaceName string, t *ast.InterfaceType) {
+	if t.Methods == nil {
+		return
+	}
+	for _, m := range t.Methods.List {
+		if len(m.Names) == 0 {
+			// embedded interface.
+			tag := p.createTag(p.renderExpr(m.Type), m.Pos(), m.End(), InterfaceMethod)
+			tag.Fields["interface"] = ifaceName
+			tag.Fields["embedded"] = "true"
+			p.tags = append(p.tags, tag)
+			continue
+		}
+		for _, name := range m.Names {
+			tag := p.createTag(name.Name, name.Pos(), m.End(), InterfaceMethod)
+			tag.Fields["interface"] = ifaceName
+			if ft, ok := m.Type.(*ast.FuncType); ok {
+				tag.Params = p.renderFields(ft.Params)
+				tag.Results = p.renderFields(ft.Results)
+			}
+			p.tags = append(p.tags, tag)
+		}
+	}
+}
+
+func (p *tagParser) createTag(name string, start, end token.Pos, tagType string) *Tag {
+	f := p.fset.File(start).Name()
+	pos := p.fset.Position(start)
+	return &Tag{
+		Name:     name,
+		File:     f,
+		Start:    strconv.Itoa(pos.Line),
+		End:      strconv.Itoa(p.fset.Position(end).Line),
+		Type:     tagType,
+		RecvType: []string{},
+		RecvName: [][]string{{}},
+		Pattern:  p.lineText(pos.Line),
+		Line:     pos.Line,
+		Offset:   pos.Offset - (pos.Column - 1),
+		Fields:   map[string]string{"access": access(name)},
+	}
+}
+
+// docFor returns the doc comment and annotations associated with node,
+// as recorded in p.cmap.
+func (p *tagParser) docFor(node ast.Node) (string, map[string][]string) {
+	groups := p.cmap[node]
+	if len(groups) == 0 {
+		return "", nil
+	}
+	var docLines []string
+	annotations := map[string][]string{}
+	for _, g := range groups {
+		gotDoc, gotAnnotations := parseAnnotations(g)
+		if gotDoc != "" {
+			docLines = append(docLines, gotDoc)
+		}
+		for k, v := range gotAnnotations {
+			annotations[k] = append(annotations[k], v...)
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	return strings.Join(docLines, "\n"), annotations
+}
+
+// access reports "public" for exported identifiers and "private"
+// otherwise, following Go's own exportedness rule. name may be a
+// printer-rendered type expression (as for an embedded field, e.g.
+// "*io.Reader"), so a leading "*" and package qualifier are stripped
+// first to reach the identifier whose exportedness actually governs
+// access to the embedded field.
+func access(name string) string {
+	name = strings.TrimPrefix(name, "*")
+	if i := strings.LastIndex(name, "."); i != -1 {
+		name = name[i+1:]
+	}
+	if name == "" {
+		return "private"
+	}
+	if unicode.IsUpper([]rune(name)[0]) {
+		return "public"
+	}
+	return "private"
+}
+
+// lineText returns the 1-indexed source line n, stripped of its
+// trailing carriage return if the file uses CRLF endings.
+func (p *tagParser) lineText(n int) string {
+	if n < 1 || n > len(p.lines) {
+		return ""
+	}
+	return string(bytes.TrimSuffix(p.lines[n-1], []byte("\r")))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStructFields(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"type Wrapper struct {\n"+
+		"\t*io.Reader\n"+
+		"\tprivateField int\n"+
+		"\tPublicField  int\n"+
+		"}\n")
+
+	tags, err := Parse(filepath.Join(dir, "sample.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	byName := map[string]*Tag{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+
+	embedded, ok := byName["*io.Reader"]
+	if !ok {
+		t.Fatalf("embedded *io.Reader field not found in %+v", tags)
+	}
+	if embedded.Fields["struct"] != "Wrapper" || embedded.Fields["embedded"] != "true" {
+		t.Fatalf("embedded field has wrong parent/embedded fields: %+v", embedded.Fields)
+	}
+	if embedded.Fields["access"] != "public" {
+		t.Fatalf("embedded *io.Reader should be access:public (io.Reader is exported), got %q", embedded.Fields["access"])
+	}
+
+	priv, ok := byName["privateField"]
+	if !ok || priv.Fields["access"] != "private" {
+		t.Fatalf("privateField should be access:private, got %+v", priv)
+	}
+
+	pub, ok := byName["PublicField"]
+	if !ok || pub.Fields["access"] != "public" {
+		t.Fatalf("PublicField should be access:public, got %+v", pub)
+	}
+}
+
+func TestParseInterfaceMethods(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"type Reader interface {\n"+
+		"\tio.Closer\n"+
+		"\tRead(p []byte) (n int, err error)\n"+
+		"}\n")
+
+	tags, err := Parse(filepath.Join(dir, "sample.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	byName := map[string]*Tag{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+
+	embedded, ok := byName["io.Closer"]
+	if !ok {
+		t.Fatalf("embedded io.Closer method not found in %+v", tags)
+	}
+	if embedded.Fields["interface"] != "Reader" || embedded.Fields["embedded"] != "true" {
+		t.Fatalf("embedded interface has wrong parent/embedded fields: %+v", embedded.Fields)
+	}
+
+	read, ok := byName["Read"]
+	if !ok {
+		t.Fatalf("Read method not found in %+v", tags)
+	}
+	if read.Fields["interface"] != "Reader" {
+		t.Fatalf("Read.Fields[interface] = %q, want Reader", read.Fields["interface"])
+	}
+	if len(read.Params) != 1 || read.Params[0].Name != "p" || read.Params[0].Type != "[]byte" {
+		t.Fatalf("Read.Params = %+v, want one param p []byte", read.Params)
+	}
+	if len(read.Results) != 2 || read.Results[0].Name != "n" || read.Results[1].Name != "err" {
+		t.Fatalf("Read.Results = %+v, want (n int, err error)", read.Results)
+	}
+}
+
+func TestParseGenDeclConstVarTypeImport(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"import \"fmt\"\n\n"+
+		"const Answer = 42\n\n"+
+		"var Name = \"sample\"\n\n"+
+		"type ID int\n\n"+
+		"var _ = fmt.Sprint\n")
+
+	tags, err := Parse(filepath.Join(dir, "sample.go"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	byName := map[string]*Tag{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+
+	if tag, ok := byName[`"fmt"`]; !ok || tag.Type != Import {
+		t.Fatalf(`expected an Import tag named "fmt", got %+v`, tags)
+	}
+	if tag, ok := byName["Answer"]; !ok || tag.Type != Constant {
+		t.Fatalf("expected a Constant tag named Answer, got %+v", tags)
+	}
+	if tag, ok := byName["Name"]; !ok || tag.Type != Variable {
+		t.Fatalf("expected a Variable tag named Name, got %+v", tags)
+	}
+	if tag, ok := byName["ID"]; !ok || tag.Type != Type {
+		t.Fatalf("expected a Type tag named ID, got %+v", tags)
+	}
+	if _, ok := byName["_"]; ok {
+		t.Fatalf("blank identifier var should not produce a tag, got %+v", tags)
+	}
+}
+
+func TestAccessStripsPointerAndPackageQualifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "private"},
+		{"lower", "private"},
+		{"Upper", "public"},
+		{"*io.Reader", "public"},
+		{"sync.Mutex", "public"},
+		{"*sample.private", "private"},
+	}
+	for _, tt := range tests {
+		if got := access(tt.name); got != tt.want {
+			t.Errorf("access(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
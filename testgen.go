@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	gofmt "go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// GenerateTests emits a TestXxx skeleton into file's sibling _test.go
+// for every Function/Method tag in tags that isn't already covered by
+// an existing test, matches only (if non-nil) and doesn't match excl
+// (if non-nil). ifaceStubs enables generating stub implementations of
+// locally-declared interface parameters instead of nil.
+func GenerateTests(file string, tags []*Tag, only, excl *regexp.Regexp, ifaceStubs bool) error {
+	pkg, err := packageName(file)
+	if err != nil {
+		return err
+	}
+
+	testFile := strings.TrimSuffix(file, ".go") + "_test.go"
+	existing, err := existingTestNames(testFile)
+	if err != nil {
+		return err
+	}
+
+	ifaces := map[string][]*Tag{} // interface name -> its InterfaceMethod tags
+	for _, t := range tags {
+		if t.Type == InterfaceMethod {
+			ifaces[t.Fields["interface"]] = append(ifaces[t.Fields["interface"]], t)
+		}
+	}
+
+	var out strings.Builder
+	stubbed := map[string]bool{}
+	wrote := false
+
+	for _, t := range tags {
+		if t.Type != Function && t.Type != Method {
+			continue
+		}
+		if only != nil && !only.MatchString(t.Name) {
+			continue
+		}
+		if excl != nil && excl.MatchString(t.Name) {
+			continue
+		}
+		if existing[testName(t)] {
+			continue
+		}
+
+		if ifaceStubs {
+			for _, param := range append(append([]Param{}, t.Params...), t.Results...) {
+				if methods, ok := ifaces[param.Type]; ok && !stubbed[param.Type] {
+					out.WriteString(renderIfaceStub(param.Type, methods))
+					out.WriteString("\n")
+					stubbed[param.Type] = true
+				}
+			}
+		}
+
+		out.WriteString(renderTestSkeleton(t))
+		out.WriteString("\n")
+		wrote = true
+	}
+
+	if !wrote {
+		return nil
+	}
+
+	var buf strings.Builder
+	if len(existing) == 0 {
+		fmt.Fprintf(&buf, "package %s\n\nimport \"testing\"\n\n", pkg)
+	} else {
+		prior, err := os.ReadFile(testFile)
+		if err != nil {
+			return err
+		}
+		buf.Write(prior)
+		buf.WriteString("\n")
+	}
+	buf.WriteString(out.String())
+
+	src, err := gofmt.Source([]byte(buf.String()))
+	if err != nil {
+		// Don't write unformatted (and possibly invalid) source to disk;
+		// a code generator that silently leaves broken output is worse
+		// than one that fails loudly.
+		return fmt.Errorf("%s: generated test source failed to gofmt: %w", testFile, err)
+	}
+	return os.WriteFile(testFile, src, 0o644)
+}
+
+// testName returns the TestXxx name GenerateTests uses for tag t,
+// capitalizing an unexported func/method name as gotests does.
+func testName(t *Tag) string {
+	name := t.Name
+	if !t.Exported {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	if t.Type == Method && len(t.RecvType) > 0 {
+		return "Test" + strings.TrimPrefix(t.RecvType[0], "*") + "_" + name
+	}
+	return "Test" + name
+}
+
+// renderTestSkeleton renders a table-driven TestXxx skeleton for tag t.
+func renderTestSkeleton(t *Tag) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "func %s(t *testing.T) {\n", testName(t))
+
+	if len(t.Params) > 0 {
+		b.WriteString("\ttype args struct {\n")
+		for i, p := range t.Params {
+			name := p.Name
+			if name == "" {
+				name = fmt.Sprintf("in%d", i)
+			}
+			typ := p.Type
+			if p.Variadic {
+				// "..." is only valid in a func signature, not a
+				// struct field; a variadic param's args hold a slice.
+				typ = "[]" + typ
+			}
+			fmt.Fprintf(&b, "\t\t%s %s\n", name, typ)
+		}
+		b.WriteString("\t}\n")
+	}
+
+	b.WriteString("\ttests := []struct {\n\t\tname string\n")
+	if len(t.Params) > 0 {
+		b.WriteString("\t\targs args\n")
+	}
+	for i, r := range t.Results {
+		fmt.Fprintf(&b, "\t\twant%s %s\n", resultName(i, r), r.Type)
+	}
+	b.WriteString("\t}{\n\t\t// TODO: add test cases.\n\t}\n")
+
+	b.WriteString("\tfor _, tt := range tests {\n\t\tt.Run(tt.name, func(t *testing.T) {\n")
+	fmt.Fprintf(&b, "\t\t\t%s\n", renderCall(t))
+	b.WriteString("\t\t})\n\t}\n}\n")
+	return b.String()
+}
+
+// renderCall renders the call under test, assigning results to
+// got/got1/... for the caller to assert on.
+func renderCall(t *Tag) string {
+	var args []string
+	for i, p := range t.Params {
+		name := p.Name
+		if name == "" {
+			name = fmt.Sprintf("in%d", i)
+		}
+		arg := "tt.args." + name
+		if p.Variadic {
+			arg += "..."
+		}
+		args = append(args, arg)
+	}
+
+	var callee string
+	if t.Type == Method {
+		recvType := ""
+		if len(t.RecvType) > 0 {
+			recvType = t.RecvType[0]
+		}
+		ptr := ""
+		if t.RecvPtr {
+			ptr = "&"
+		}
+		callee = fmt.Sprintf("(%s%s{}).%s", ptr, recvType, t.Name)
+	} else {
+		callee = t.Name
+	}
+
+	call := fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", "))
+	if len(t.Results) == 0 {
+		return call
+	}
+
+	names := make([]string, len(t.Results))
+	blanks := make([]string, len(t.Results))
+	for i := range t.Results {
+		names[i] = "got" + resultName(i, t.Results[i])
+		blanks[i] = "_"
+	}
+	return fmt.Sprintf("%s := %s\n\t\t\t%s = %s",
+		strings.Join(names, ", "), call, strings.Join(blanks, ", "), strings.Join(names, ", "))
+}
+
+// resultName numbers unnamed results as gotests does: "", "1", "2", ...
+func resultName(i int, r Param) string {
+	if r.Name != "" {
+		return strings.ToUpper(r.Name[:1]) + r.Name[1:]
+	}
+	if i == 0 {
+		return ""
+	}
+	return fmt.Sprint(i)
+}
+
+// renderIfaceStub renders a minimal struct implementing a locally
+// declared interface, for use as a parameter's zero value under -i.
+func renderIfaceStub(name string, methods []*Tag) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %sStub struct{}\n\n", name)
+	for _, m := range methods {
+		if m.Fields["embedded"] == "true" {
+			continue
+		}
+		params := make([]string, len(m.Params))
+		for i, p := range m.Params {
+			pname := p.Name
+			if pname == "" {
+				pname = fmt.Sprintf("in%d", i)
+			}
+			typ := p.Type
+			if p.Variadic {
+				typ = "..." + typ
+			}
+			params[i] = fmt.Sprintf("%s %s", pname, typ)
+		}
+		results := make([]string, len(m.Results))
+		for i, r := range m.Results {
+			results[i] = r.Type
+		}
+		retList := strings.Join(results, ", ")
+		if len(results) > 1 {
+			retList = "(" + retList + ")"
+		}
+		zeros := make([]string, len(m.Results))
+		for i, r := range m.Results {
+			zeros[i] = zeroValue(r.Type)
+		}
+		fmt.Fprintf(&b, "func (*%sStub) %s(%s) %s {\n\treturn %s\n}\n\n",
+			name, m.Name, strings.Join(params, ", "), retList, strings.Join(zeros, ", "))
+	}
+	return b.String()
+}
+
+// zeroValue renders a best-effort zero value expression for a rendered
+// type string, for use in generated stub method bodies.
+func zeroValue(typ string) string {
+	switch {
+	case typ == "string":
+		return `""`
+	case typ == "bool":
+		return "false"
+	case typ == "error" || typ == "any":
+		return "nil"
+	case isNumericType(typ):
+		return "0"
+	case strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") ||
+		strings.HasPrefix(typ, "map[") || strings.HasPrefix(typ, "chan ") ||
+		strings.HasPrefix(typ, "func(") || strings.HasPrefix(typ, "interface{"):
+		return "nil"
+	default:
+		return typ + "{}"
+	}
+}
+
+func isNumericType(typ string) bool {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "byte", "rune", "complex64", "complex128":
+		return true
+	}
+	return false
+}
+
+// packageName reads just the package clause of file.
+func packageName(file string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	return f.Name.Name, nil
+}
+
+// existingTestNames returns the set of TestXxx func names already
+// present in testFile, so GenerateTests can skip funcs already covered.
+func existingTestNames(testFile string) (map[string]bool, error) {
+	names := map[string]bool{}
+	if _, err := os.Stat(testFile); err != nil {
+		if os.IsNotExist(err) {
+			return names, nil
+		}
+		return nil, err
+	}
+	tags, err := Parse(testFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		if (t.Type == Function || t.Type == Method) && strings.HasPrefix(t.Name, "Test") {
+			names[t.Name] = true
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTestsSkipsExistingTests(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"func Add(a, b int) int {\n\treturn a + b\n}\n\n"+
+		"func Sub(a, b int) int {\n\treturn a - b\n}\n")
+	writeFile(t, dir, "sample_test.go", "package sample\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\n")
+
+	src := filepath.Join(dir, "sample.go")
+	tags, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := GenerateTests(src, tags, nil, nil, false); err != nil {
+		t.Fatalf("GenerateTests: %v", err)
+	}
+
+	content := readFile(t, filepath.Join(dir, "sample_test.go"))
+	if n := strings.Count(content, "func TestAdd("); n != 1 {
+		t.Fatalf("TestAdd should not be duplicated (found %d), got:\n%s", n, content)
+	}
+	if !strings.Contains(content, "func TestSub(t *testing.T) {") {
+		t.Fatalf("expected a generated TestSub skeleton, got:\n%s", content)
+	}
+}
+
+func TestGenerateTestsRespectsOnlyAndExcl(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sample.go", "package sample\n\n"+
+		"func Add(a, b int) int {\n\treturn a + b\n}\n\n"+
+		"func private() {}\n")
+
+	src := filepath.Join(dir, "sample.go")
+	tags, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	only := regexp.MustCompile("^Add$")
+	if err := GenerateTests(src, tags, only, nil, false); err != nil {
+		t.Fatalf("GenerateTests: %v", err)
+	}
+
+	content := readFile(t, filepath.Join(dir, "sample_test.go"))
+	if !strings.Contains(content, "func TestAdd(") {
+		t.Fatalf("expected TestAdd, got:\n%s", content)
+	}
+	if strings.Contains(content, "private") {
+		t.Fatalf("-only should have filtered out private, got:\n%s", content)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", path, err)
+	}
+	return string(b)
+}